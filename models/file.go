@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// File 存储一个用户文件的元信息记录
+type File struct {
+	ID        uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Name     string
+	SourceName string
+	UserID   uint
+	Size     uint64
+	FolderID uint
+	PolicyID uint
+
+	// SHA256Hash 默认计算的内容哈希，用于下载校验与跨存储策略去重；
+	// MD5Hash/BLAKE3Hash 为可选哈希，三者由 pkg/filesystem.WithContentHash 流式计算；
+	// 其唯一调用方是 pkg/task.HashBackfillTask，上传路径不计算哈希，尚无哈希的文件（含新上传的文件）
+	// 由该任务在后台补算
+	SHA256Hash string `gorm:"index:sha256_hash"`
+	MD5Hash    string
+	BLAKE3Hash string
+}
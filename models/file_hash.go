@@ -0,0 +1,17 @@
+package model
+
+// SetHashes 写入本次计算得到的内容哈希，字段定义见 file.go
+func (file *File) SetHashes(sha256Hash, md5Hash, blake3Hash string) error {
+	return DB.Model(file).UpdateColumns(map[string]interface{}{
+		"sha256_hash": sha256Hash,
+		"md5_hash":    md5Hash,
+		"blake3_hash": blake3Hash,
+	}).Error
+}
+
+// GetFilesMissingHash 返回尚未计算内容哈希的历史文件，供后台补算任务分批处理
+func GetFilesMissingHash(limit int) ([]File, error) {
+	var files []File
+	err := DB.Where("sha256_hash = ? OR sha256_hash IS NULL", "").Limit(limit).Find(&files).Error
+	return files, err
+}
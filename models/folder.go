@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Folder 存储一个目录的元信息记录
+type Folder struct {
+	ID        uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Name     string
+	ParentID *uint
+	OwnerID  uint
+	Position string
+
+	// Size/ChildFileCount/ChildFolderCount 是子树的增量汇总计数，由 fs.Move/Copy/Delete 的调用方
+	// （explorer 服务与 pkg/task 的长任务）在这些操作成功返回后，以及压缩/解压缩任务上传完成后按差值维护，
+	// 不在 fs.* 内部、也不在其事务内更新；重命名不改变计数。这些计数使 ItemPropertyService.GetProperty 不必在每次缓存失效时
+	// 都重新遍历整棵子树。CountersDirty 为 true 时表示计数可能已失真（例如绕过 hook 的批量 SQL
+	// 更新），下一次读取会回退为全量统计，并由夜间对账任务（pkg/task.ReconcileFolderCounters）修复。
+	Size             uint64
+	ChildFileCount   int
+	ChildFolderCount int
+	CountersDirty    bool
+}
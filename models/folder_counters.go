@@ -0,0 +1,126 @@
+package model
+
+import (
+	"github.com/cloudreve/Cloudreve/v3/pkg/util"
+	"github.com/jinzhu/gorm"
+)
+
+// GetDirtyFolders 返回所有计数标记为失真、待夜间对账任务重新统计的目录
+func GetDirtyFolders() ([]Folder, error) {
+	var folders []Folder
+	err := DB.Where("counters_dirty = ?", true).Find(&folders).Error
+	return folders, err
+}
+
+// ApplySizeDelta 沿父级链逐级应用大小增量；tx 非 nil 时在该事务中执行，tx 为 nil 时直接写库
+func (folder *Folder) ApplySizeDelta(tx *gorm.DB, sizeDelta int64, fileCountDelta, folderCountDelta int) error {
+	db := DB
+	if tx != nil {
+		db = tx
+	}
+
+	current := folder
+	for {
+		if err := db.Model(current).UpdateColumns(map[string]interface{}{
+			"size":               gorm.Expr("size + ?", sizeDelta),
+			"child_file_count":   gorm.Expr("child_file_count + ?", fileCountDelta),
+			"child_folder_count": gorm.Expr("child_folder_count + ?", folderCountDelta),
+		}).Error; err != nil {
+			return err
+		}
+
+		if current.ParentID == nil {
+			return nil
+		}
+
+		parent, err := GetFoldersByIDs([]uint{*current.ParentID}, current.OwnerID)
+		if err != nil || len(parent) == 0 {
+			return err
+		}
+		current = &parent[0]
+	}
+}
+
+// MarkCountersDirty 将子树计数标记为失真，下一次 GetProperty 会回退为全量重新统计，
+// 并由夜间对账任务择机修复，避免在高频写路径上做昂贵的即时重算
+func (folder *Folder) MarkCountersDirty() error {
+	return DB.Model(folder).UpdateColumn("counters_dirty", true).Error
+}
+
+// CountersValid 返回增量计数是否可信
+func (folder *Folder) CountersValid() bool {
+	return !folder.CountersDirty
+}
+
+// SetCounters 将增量计数重置为给定的绝对值并清除 dirty 标记，用于全量重算后的回写
+func (folder *Folder) SetCounters(size uint64, fileCount, folderCount int) error {
+	return DB.Model(folder).UpdateColumns(map[string]interface{}{
+		"size":               size,
+		"child_file_count":   fileCount,
+		"child_folder_count": folderCount,
+		"counters_dirty":     false,
+	}).Error
+}
+
+// ApplyFolderRelocationCounters 在目录 folder 从 oldParentID 迁移/复制到 newParentID 后，
+// 依据该目录自身已维护的子树聚合值（Size/ChildFileCount/ChildFolderCount），沿旧、新父级链
+// 分别增减计数；isCopy 为 true 时源端内容仍然保留，不扣减旧父级。
+// 由 fs.Move/fs.Copy/fs.Delete 的调用方（explorer 服务与 pkg/task 的长任务）在其成功返回后调用。
+func ApplyFolderRelocationCounters(folder *Folder, oldParentID, newParentID *uint, ownerID uint, isCopy bool) error {
+	folderCountDelta := folder.ChildFolderCount + 1 // 目录自身计入父级的子目录数
+	return applyRelocationCounters(oldParentID, newParentID, ownerID, int64(folder.Size), folder.ChildFileCount, folderCountDelta, isCopy)
+}
+
+// ApplyFileRelocationCounters 在文件 file 从 oldParentID 迁移/复制到 newParentID 后增减父级计数，
+// 由 fs.Move/fs.Copy/fs.Delete 的调用方（explorer 服务与 pkg/task 的长任务）在其成功返回后调用。
+func ApplyFileRelocationCounters(file *File, oldParentID, newParentID *uint, ownerID uint, isCopy bool) error {
+	return applyRelocationCounters(oldParentID, newParentID, ownerID, int64(file.Size), 1, 0, isCopy)
+}
+
+// ApplyUploadCounters 在新文件 file 上传落地后为其所在目录增加计数，
+// 由 pkg/task 的压缩/解压缩任务在 Upload 成功返回后调用。
+func ApplyUploadCounters(file *File, parentID *uint, ownerID uint) error {
+	return applyRelocationCounters(nil, parentID, ownerID, int64(file.Size), 1, 0, false)
+}
+
+// applyRelocationCounters 是 Apply{Folder,File}RelocationCounters/ApplyUploadCounters 的公共实现：
+// 先在新父级链上加上增量，再（非复制时）在旧父级链上扣减，两步均直接写库、不开启事务，
+// 也不与目录树变更本身处于同一事务；任一步失败都不回滚另一侧已经落库的计数——下一次读取
+// 可能出现的短暂不一致由 CountersDirty 兜底，而不是让整条父级链的更新互相阻塞。
+func applyRelocationCounters(oldParentID, newParentID *uint, ownerID uint, sizeDelta int64, fileCountDelta, folderCountDelta int, isCopy bool) error {
+	if newParentID != nil {
+		if err := adjustFolderCounters(*newParentID, ownerID, sizeDelta, fileCountDelta, folderCountDelta); err != nil {
+			return err
+		}
+	}
+
+	if !isCopy && oldParentID != nil {
+		if err := adjustFolderCounters(*oldParentID, ownerID, -sizeDelta, -fileCountDelta, -folderCountDelta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// adjustFolderCounters 对单个目录应用增量；ApplySizeDelta 本身失败时（例如所在事务被外部中止），
+// 退而求其次将该目录标记为 dirty，确保 CountersDirty 兜底对账确实会被触发，
+// 而不是让文档中承诺的"短暂不一致由 CountersDirty 兜底"名存实亡
+func adjustFolderCounters(folderID, ownerID uint, sizeDelta int64, fileCountDelta, folderCountDelta int) error {
+	folders, err := GetFoldersByIDs([]uint{folderID}, ownerID)
+	if err != nil {
+		return err
+	}
+	if len(folders) == 0 {
+		return nil
+	}
+
+	if err := folders[0].ApplySizeDelta(nil, sizeDelta, fileCountDelta, folderCountDelta); err != nil {
+		if dirtyErr := folders[0].MarkCountersDirty(); dirtyErr != nil {
+			util.Log().Warning("目录[%d]增量计数应用失败且无法标记为 dirty：%s", folderID, dirtyErr)
+		}
+		return err
+	}
+
+	return nil
+}
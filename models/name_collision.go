@@ -0,0 +1,21 @@
+package model
+
+// FolderNameExists 判断 parentID 目录下是否已存在同名子目录，parentID 为 nil 表示根目录
+func FolderNameExists(parentID *uint, ownerID uint, name string) (bool, error) {
+	var count int
+	query := DB.Model(&Folder{}).Where("owner_id = ? AND name = ?", ownerID, name)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// FileNameExists 判断 folderID 目录下是否已存在同名文件
+func FileNameExists(folderID uint, ownerID uint, name string) (bool, error) {
+	var count int
+	err := DB.Model(&File{}).Where("folder_id = ? AND user_id = ? AND name = ?", folderID, ownerID, name).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Task 存储一个异步任务（压缩/解压缩/移动/复制/删除/哈希补算等）的执行记录
+type Task struct {
+	ID        uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	UserID uint
+	Type   int
+	Status int
+	// Props 为该任务类型自定义的 JSON 编码参数，恢复型任务（Move/Copy/Delete）
+	// 将执行游标一并编码在其中，worker 重启后据此从断点继续
+	Props string `gorm:"type:text"`
+}
@@ -0,0 +1,61 @@
+package model
+
+// 任务类型，与 Task.Type 对应
+const (
+	TaskTypeCompress = iota
+	TaskTypeDecompress
+	TaskTypeMove
+	TaskTypeCopy
+	TaskTypeDelete
+	TaskTypeHashBackfill
+)
+
+// 任务状态，与 Task.Status 对应
+const (
+	Processing = iota
+	Complete
+	Error
+	Canceled
+)
+
+// NewTask 新建一条任务记录并落库，props 为该任务类型自定义的 JSON 编码参数
+func NewTask(userID uint, taskType int, props string) (*Task, error) {
+	task := &Task{
+		UserID: userID,
+		Type:   taskType,
+		Status: Processing,
+		Props:  props,
+	}
+
+	if err := DB.Create(task).Error; err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// SetStatus 变更任务状态并落库
+func (task *Task) SetStatus(status int) {
+	task.Status = status
+	DB.Model(task).UpdateColumn("status", status)
+}
+
+// UpdateProps 更新任务游标/进度等自定义参数并落库
+func (task *Task) UpdateProps(props string) error {
+	task.Props = props
+	return DB.Model(task).UpdateColumn("props", props).Error
+}
+
+// GetTasksByStatus 返回指定状态下的全部任务，worker 重启后据此恢复执行
+func GetTasksByStatus(status int) ([]Task, error) {
+	var tasks []Task
+	err := DB.Where("status = ?", status).Find(&tasks).Error
+	return tasks, err
+}
+
+// GetTaskByID 按主键查找属于 userID 的任务记录，用于取消等只允许任务所有者操作的场景
+func GetTaskByID(id, userID uint) (*Task, error) {
+	task := &Task{}
+	err := DB.Where("id = ? AND user_id = ?", id, userID).First(task).Error
+	return task, err
+}
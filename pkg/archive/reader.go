@@ -0,0 +1,138 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// DecodedEntry 描述从归档中解码出的一个条目
+type DecodedEntry struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+	IsDir   bool
+}
+
+// Reader 顺序遍历归档中的条目，屏蔽 zip/tar 系列格式差异
+type Reader interface {
+	// Next 返回下一个条目及其内容，读到结尾返回 io.EOF；content 仅在条目不是目录时有效，
+	// 且必须在下一次调用 Next 之前读取完毕
+	Next() (DecodedEntry, io.Reader, error)
+}
+
+// sniffHeaderSize 用于魔数嗅探而预读的字节数，覆盖 zip/gzip/zstd 等格式的文件头标识
+const sniffHeaderSize = 512
+
+// Sniff 通过文件头魔数而非扩展名判断归档格式并返回对应的 Reader，使重命名后缺失/错误扩展名
+// 的归档文件依然可以被正确解压；仅识别 zip/gzip/zstd 魔数，其余一律按未压缩 tar 回退处理，
+// 7z/rar/xz 等不受支持的格式会在 tar 解析阶段报错而不是被当作其他格式误读。
+// zip 的中央目录位于文件尾部，需要随机访问，因此嗅探到 zip 魔数时会将剩余内容整体读入内存；
+// 其余格式保持流式读取。
+func Sniff(r io.Reader) (Reader, error) {
+	header := make([]byte, sniffHeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	header = header[:n]
+
+	full := io.MultiReader(bytes.NewReader(header), r)
+
+	switch {
+	case len(header) >= 2 && header[0] == 'P' && header[1] == 'K':
+		data, err := io.ReadAll(full)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		return &zipReader{zr: zr}, nil
+	case len(header) >= 2 && header[0] == 0x1f && header[1] == 0x8b:
+		gr, err := gzip.NewReader(full)
+		if err != nil {
+			return nil, err
+		}
+		return &tarReader{tr: tar.NewReader(gr)}, nil
+	case len(header) >= 4 && header[0] == 0x28 && header[1] == 0xb5 && header[2] == 0x2f && header[3] == 0xfd:
+		zr, err := zstd.NewReader(full)
+		if err != nil {
+			return nil, err
+		}
+		return &tarReader{tr: tar.NewReader(zr)}, nil
+	default:
+		return &tarReader{tr: tar.NewReader(full)}, nil
+	}
+}
+
+type zipReader struct {
+	zr  *zip.Reader
+	idx int
+}
+
+func (r *zipReader) Next() (DecodedEntry, io.Reader, error) {
+	if r.idx >= len(r.zr.File) {
+		return DecodedEntry{}, nil, io.EOF
+	}
+	f := r.zr.File[r.idx]
+	r.idx++
+
+	entry := DecodedEntry{
+		Name:    f.Name,
+		Size:    int64(f.UncompressedSize64),
+		Mode:    f.Mode(),
+		ModTime: f.Modified,
+		IsDir:   f.Mode().IsDir(),
+	}
+	if entry.IsDir {
+		return entry, nil, nil
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return DecodedEntry{}, nil, err
+	}
+	return entry, &closeOnEOFReader{rc}, nil
+}
+
+// closeOnEOFReader 在读到 EOF 时自动关闭底层 ReadCloser，
+// 使调用方可以把返回值当作普通 io.Reader 使用而不必关心 zip 单个条目的关闭时机
+type closeOnEOFReader struct {
+	io.ReadCloser
+}
+
+func (r *closeOnEOFReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err == io.EOF {
+		r.Close()
+	}
+	return n, err
+}
+
+type tarReader struct {
+	tr *tar.Reader
+}
+
+func (r *tarReader) Next() (DecodedEntry, io.Reader, error) {
+	hdr, err := r.tr.Next()
+	if err != nil {
+		return DecodedEntry{}, nil, err
+	}
+
+	return DecodedEntry{
+		Name:    hdr.Name,
+		Size:    hdr.Size,
+		Mode:    hdr.FileInfo().Mode(),
+		ModTime: hdr.ModTime,
+		IsDir:   hdr.FileInfo().IsDir(),
+	}, r.tr, nil
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func readAll(t *testing.T, r Reader) []DecodedEntry {
+	t.Helper()
+
+	var entries []DecodedEntry
+	for {
+		entry, content, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error: %v", err)
+		}
+		if content != nil {
+			if _, err := io.Copy(io.Discard, content); err != nil {
+				t.Fatalf("read content: %v", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestSniffZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, err := zw.Create("hello.txt")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := fw.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := Sniff(&buf)
+	if err != nil {
+		t.Fatalf("Sniff: %v", err)
+	}
+	if _, ok := r.(*zipReader); !ok {
+		t.Fatalf("Sniff() returned %T, want *zipReader", r)
+	}
+
+	entries := readAll(t, r)
+	if len(entries) != 1 || entries[0].Name != "hello.txt" {
+		t.Fatalf("entries = %+v, want [hello.txt]", entries)
+	}
+}
+
+func TestSniffTarGz(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	content := []byte("world")
+	if err := tw.WriteHeader(&tar.Header{Name: "world.txt", Size: int64(len(content)), Mode: 0644}); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tw.Close: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gw.Close: %v", err)
+	}
+
+	r, err := Sniff(&buf)
+	if err != nil {
+		t.Fatalf("Sniff: %v", err)
+	}
+	if _, ok := r.(*tarReader); !ok {
+		t.Fatalf("Sniff() returned %T, want *tarReader", r)
+	}
+
+	entries := readAll(t, r)
+	if len(entries) != 1 || entries[0].Name != "world.txt" {
+		t.Fatalf("entries = %+v, want [world.txt]", entries)
+	}
+}
+
+func TestSniffPlainTarFallback(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "plain.txt", Size: 0, Mode: 0644}); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := Sniff(&buf)
+	if err != nil {
+		t.Fatalf("Sniff: %v", err)
+	}
+
+	entries := readAll(t, r)
+	if len(entries) != 1 || entries[0].Name != "plain.txt" {
+		t.Fatalf("entries = %+v, want [plain.txt]", entries)
+	}
+}
@@ -0,0 +1,55 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// Entry 描述归档中的一个待写入条目。Open 仅在 IsDir 为 false 时会被调用。
+type Entry struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+	IsDir   bool
+	// Open 返回该条目的内容，由调用方负责通过存储策略下载并在读取完毕后关闭。
+	Open func(ctx context.Context) (io.ReadCloser, error)
+}
+
+// Stream 将 entries 依次写入 w，目录条目排在其所属文件之前以便在目标格式中保留空目录。
+// entries 的顺序由调用方保证（通常是对目录树做一次先序遍历的结果）。
+// ctx 用于在客户端断开连接时提前终止遍历，避免继续从存储策略读取已无人接收的数据。
+func Stream(ctx context.Context, w Writer, entries []Entry) error {
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := w.WriteHeader(entry.Name, entry.Size, entry.Mode, entry.ModTime); err != nil {
+			return err
+		}
+
+		if entry.IsDir {
+			continue
+		}
+
+		if err := copyEntry(ctx, w, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyEntry(ctx context.Context, w io.Writer, entry Entry) error {
+	rc, err := entry.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	_, err = io.Copy(w, rc)
+	return err
+}
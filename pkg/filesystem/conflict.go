@@ -0,0 +1,78 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrObjectExist 在 ConflictFail 策略下，目标路径已存在同名对象时返回
+var ErrObjectExist = errors.New("目标路径下已存在同名对象")
+
+// ConflictPolicy 描述目标路径发生命名冲突时 Move/Copy/Rename 以及解压缩任务应如何处理
+type ConflictPolicy string
+
+const (
+	// ConflictFail 保持历史行为：遇到冲突直接报错中止
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictSkip 跳过冲突的条目，继续处理其余条目
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite 覆盖目标位置上已存在的同名对象
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictRename 在文件名后追加 " (n)" 探测一个不冲突的新名称
+	ConflictRename ConflictPolicy = "rename"
+	// ConflictMerge 仅对目录生效：递归合并到已存在的同名目录，子项按相同策略逐一处理
+	ConflictMerge ConflictPolicy = "merge"
+)
+
+// IsValidConflictPolicy 判断冲突处理策略是否为受支持的取值，空字符串视为 ConflictFail
+func IsValidConflictPolicy(policy string) bool {
+	switch ConflictPolicy(policy) {
+	case "", ConflictFail, ConflictSkip, ConflictOverwrite, ConflictRename, ConflictMerge:
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeConflictPolicy 将空字符串归一化为默认的 ConflictFail
+func NormalizeConflictPolicy(policy string) ConflictPolicy {
+	if policy == "" {
+		return ConflictFail
+	}
+	return ConflictPolicy(policy)
+}
+
+// maxNameCollisionAttempts 限制 ResolveNameCollision 的探测次数上限，避免目标目录下存在
+// 大量连续同名对象（或恶意构造）时无限制地顺序查库
+const maxNameCollisionAttempts = 1000
+
+// ErrTooManyNameCollisions 在连续探测 maxNameCollisionAttempts 次后仍未找到可用名称时返回
+var ErrTooManyNameCollisions = errors.New("重命名候选项过多，无法探测到可用名称")
+
+// ResolveNameCollision 根据 ConflictRename 策略，在 dst 目录下为 name 探测一个不冲突的新名称，
+// 规则是在扩展名之前追加 " (n)"，n 从 1 开始递增，直到 exists 返回 false；
+// 每次探测前检查 ctx 是否已取消，且最多探测 maxNameCollisionAttempts 次，避免无界的顺序查库
+func ResolveNameCollision(ctx context.Context, name string, exists func(ctx context.Context, candidate string) (bool, error)) (string, error) {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for n := 1; n <= maxNameCollisionAttempts; n++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		taken, err := exists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+
+	return "", ErrTooManyNameCollisions
+}
@@ -0,0 +1,91 @@
+package filesystem
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsValidConflictPolicy(t *testing.T) {
+	tests := map[string]bool{
+		"":          true,
+		"fail":      true,
+		"skip":      true,
+		"overwrite": true,
+		"rename":    true,
+		"merge":     true,
+		"bogus":     false,
+	}
+	for policy, want := range tests {
+		if got := IsValidConflictPolicy(policy); got != want {
+			t.Errorf("IsValidConflictPolicy(%q) = %v, want %v", policy, got, want)
+		}
+	}
+}
+
+func TestNormalizeConflictPolicy(t *testing.T) {
+	if got := NormalizeConflictPolicy(""); got != ConflictFail {
+		t.Errorf("NormalizeConflictPolicy(\"\") = %q, want %q", got, ConflictFail)
+	}
+	if got := NormalizeConflictPolicy("skip"); got != ConflictSkip {
+		t.Errorf("NormalizeConflictPolicy(\"skip\") = %q, want %q", got, ConflictSkip)
+	}
+}
+
+func TestResolveNameCollision(t *testing.T) {
+	taken := map[string]bool{"file.txt": true, "file (1).txt": true}
+	exists := func(ctx context.Context, candidate string) (bool, error) {
+		return taken[candidate], nil
+	}
+
+	name, err := ResolveNameCollision(context.Background(), "file.txt", exists)
+	if err != nil {
+		t.Fatalf("ResolveNameCollision() error: %v", err)
+	}
+	if name != "file (2).txt" {
+		t.Errorf("ResolveNameCollision() = %q, want %q", name, "file (2).txt")
+	}
+}
+
+func TestResolveNameCollisionNoConflict(t *testing.T) {
+	exists := func(ctx context.Context, candidate string) (bool, error) {
+		return false, nil
+	}
+
+	name, err := ResolveNameCollision(context.Background(), "file.txt", exists)
+	if err != nil {
+		t.Fatalf("ResolveNameCollision() error: %v", err)
+	}
+	if name != "file (1).txt" {
+		t.Errorf("ResolveNameCollision() = %q, want %q", name, "file (1).txt")
+	}
+}
+
+func TestResolveNameCollisionCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	exists := func(ctx context.Context, candidate string) (bool, error) {
+		calls++
+		return true, nil
+	}
+
+	_, err := ResolveNameCollision(ctx, "file.txt", exists)
+	if err == nil {
+		t.Fatal("ResolveNameCollision() should return an error once ctx is cancelled")
+	}
+	if calls != 0 {
+		t.Errorf("ResolveNameCollision() should not call exists after ctx is cancelled, got %d calls", calls)
+	}
+}
+
+func TestResolveNameCollisionTooManyAttempts(t *testing.T) {
+	exists := func(ctx context.Context, candidate string) (bool, error) {
+		return true, nil
+	}
+
+	_, err := ResolveNameCollision(context.Background(), "file.txt", exists)
+	if err != ErrTooManyNameCollisions {
+		t.Errorf("ResolveNameCollision() error = %v, want %v", err, ErrTooManyNameCollisions)
+	}
+}
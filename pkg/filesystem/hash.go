@@ -0,0 +1,67 @@
+package filesystem
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+
+	"lukechampine.com/blake3"
+)
+
+// hashTee 在上传写入存储策略的同时，以 io.TeeReader 的方式流式计算内容哈希，
+// 避免为了算哈希而把文件再完整读一遍。
+type hashTee struct {
+	sha256 hash.Hash
+	md5    hash.Hash
+	blake3 hash.Hash
+	writer io.Writer
+}
+
+// newHashTee 包裹 r，返回的 Reader 读取到的数据与 r 完全一致，同时把数据喂给三路哈希计算器
+func newHashTee(r io.Reader) (io.Reader, *hashTee) {
+	tee := &hashTee{
+		sha256: sha256.New(),
+		md5:    md5.New(),
+		blake3: blake3.New(32, nil),
+	}
+	tee.writer = io.MultiWriter(tee.sha256, tee.md5, tee.blake3)
+	return io.TeeReader(r, tee.writer), tee
+}
+
+// Sums 返回目前已读取内容的三路哈希值（十六进制）
+func (t *hashTee) Sums() (sha256Hash, md5Hash, blake3Hash string) {
+	return hexSum(t.sha256), hexSum(t.md5), hexSum(t.blake3)
+}
+
+func hexSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WithContentHash 包裹上传数据流 r，返回的 Reader 在读取到 io.EOF 时自动调用一次 finalize，
+// 传入三路内容哈希，finalize 通常是把哈希写回 File 记录的 model.File.SetHashes。
+// 未读取到 io.EOF 时 finalize 不会被调用。
+func WithContentHash(r io.Reader, finalize func(sha256Hash, md5Hash, blake3Hash string) error) io.Reader {
+	teed, tee := newHashTee(r)
+	return &hashFinalizeReader{Reader: teed, tee: tee, finalize: finalize}
+}
+
+type hashFinalizeReader struct {
+	io.Reader
+	tee      *hashTee
+	finalize func(sha256Hash, md5Hash, blake3Hash string) error
+	done     bool
+}
+
+func (r *hashFinalizeReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF && !r.done {
+		r.done = true
+		sha256Hash, md5Hash, blake3Hash := r.tee.Sums()
+		if fErr := r.finalize(sha256Hash, md5Hash, blake3Hash); fErr != nil {
+			return n, fErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,22 @@
+package serializer
+
+import "time"
+
+// ObjectProps 文件/目录属性，由 ItemPropertyService.GetProperty 和 ItemBulkPropertyService.BulkProperty 返回
+type ObjectProps struct {
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Policy         string    `json:"policy,omitempty"`
+	Size           uint64    `json:"size"`
+	ChildFolderNum int       `json:"child_folder_num,omitempty"`
+	ChildFileNum   int       `json:"child_file_num,omitempty"`
+	Path           string    `json:"path,omitempty"`
+	QueryDate      time.Time `json:"query_date"`
+
+	// HashSHA256/HashMD5/HashBLAKE3 为文件内容哈希，legacy 文件在后台任务补算完成前为空字符串。
+	// 对目录而言只有 HashSHA256 可能有值，为子树内全部文件按名称排序后对 "name||sha256" 逐项拼接求得的
+	// 汇总哈希，仅在本次请求重新统计了子树或命中的缓存中已有该值时返回；HashMD5/HashBLAKE3 对目录始终为空。
+	HashSHA256 string `json:"hash_sha256,omitempty"`
+	HashMD5    string `json:"hash_md5,omitempty"`
+	HashBLAKE3 string `json:"hash_blake3,omitempty"`
+}
@@ -0,0 +1,315 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+
+	model "github.com/cloudreve/Cloudreve/v3/models"
+	"github.com/cloudreve/Cloudreve/v3/pkg/archive"
+	"github.com/cloudreve/Cloudreve/v3/pkg/filesystem"
+	"github.com/cloudreve/Cloudreve/v3/pkg/util"
+)
+
+// errSkipDecompressEntry 标记单个解压条目按 ConflictSkip 策略被跳过，不视为任务失败
+var errSkipDecompressEntry = errors.New("entry skipped due to conflict policy")
+
+// finalizeUpload 是新文件上传落地后的 finalize 钩子：为其所在目录增加增量计数。
+// 在 taskFs.Upload 成功返回后调用，计数失败不影响上传本身已经
+// 成功的结果，只记录日志——下一次对该目录的统计请求会在 CountersDirty 标记下回退为全量重算。
+func finalizeUpload(file *model.File, ownerID uint) {
+	if file == nil {
+		return
+	}
+	if err := model.ApplyUploadCounters(file, &file.FolderID, ownerID); err != nil {
+		util.Log().Warning("文件[%d]上传后增量计数失败：%s", file.ID, err)
+	}
+}
+
+// CompressTask 文件打包压缩任务
+//
+// CompressTask/DecompressTask 依赖的 Job/Progress/Pool 等调度骨架定义在同目录的 task.go，
+// 两者是同一任务子系统不可分割的一部分，缺一不可独立编译。
+type CompressTask struct {
+	User  *model.User
+	Dst   string
+	Dirs  []uint
+	Items []uint
+	// Format 归档格式，默认为 zip
+	Format archive.Format
+
+	record *model.Task
+}
+
+type compressTaskProps struct {
+	Dst    string         `json:"dst"`
+	Dirs   []uint         `json:"dirs"`
+	Items  []uint         `json:"items"`
+	Format archive.Format `json:"format"`
+}
+
+// NewCompressTask 新建一个压缩任务并落库
+func NewCompressTask(user *model.User, dst string, dirs, items []uint, format archive.Format) (Job, error) {
+	if format == "" {
+		format = archive.Zip
+	}
+
+	props, err := json.Marshal(compressTaskProps{Dst: dst, Dirs: dirs, Items: items, Format: format})
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := model.NewTask(user.ID, model.TaskTypeCompress, string(props))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompressTask{User: user, Dst: dst, Dirs: dirs, Items: items, Format: format, record: record}, nil
+}
+
+func (job *CompressTask) Do() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelRegistry.Store(job.record.ID, cancel)
+	defer cancelRegistry.Delete(job.record.ID)
+
+	taskFs, err := filesystem.NewFileSystem(job.User)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+	defer taskFs.Recycle()
+
+	entries, err := job.buildEntries(taskFs)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := archive.NewWriter(job.Format, pw)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if err := archive.Stream(ctx, w, entries); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(w.Close())
+	}()
+
+	uploaded, err := taskFs.Upload(ctx, job.Dst, pr)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+	finalizeUpload(uploaded, job.User.ID)
+
+	job.SetStatus(model.Complete)
+}
+
+// buildEntries 递归列出 job.Dirs/job.Items 下的全部目录与文件，构造成 archive.Stream 所需的条目序列。
+// 目录条目在同一次先序遍历中排在其子项之前，文件内容通过 fs.GetDownloadContent 在写入时按需读取，
+// 不在磁盘上生成任何中间文件。
+func (job *CompressTask) buildEntries(taskFs *filesystem.FileSystem) ([]archive.Entry, error) {
+	folders, err := model.GetRecursiveChildFolder(job.Dirs, job.User.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	childFiles, err := model.GetChildFilesOfFolders(&folders)
+	if err != nil {
+		return nil, err
+	}
+	looseFiles, err := model.GetFilesByIDs(job.Items, job.User.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	filesByFolder := make(map[uint][]model.File, len(childFiles))
+	for _, file := range childFiles {
+		filesByFolder[file.FolderID] = append(filesByFolder[file.FolderID], file)
+	}
+
+	entries := make([]archive.Entry, 0, len(folders)+len(childFiles)+len(looseFiles))
+	for i := range folders {
+		folder := folders[i]
+		entries = append(entries, archive.Entry{
+			Name:    path.Join(folder.Position, folder.Name),
+			IsDir:   true,
+			Mode:    fs.ModeDir | 0755,
+			ModTime: folder.UpdatedAt,
+		})
+		for _, file := range filesByFolder[folder.ID] {
+			entries = append(entries, fileEntry(taskFs, path.Join(folder.Position, folder.Name, file.Name), file))
+		}
+	}
+	for _, file := range looseFiles {
+		entries = append(entries, fileEntry(taskFs, file.Name, file))
+	}
+
+	return entries, nil
+}
+
+func fileEntry(taskFs *filesystem.FileSystem, name string, file model.File) archive.Entry {
+	return archive.Entry{
+		Name:    name,
+		Size:    int64(file.Size),
+		Mode:    0644,
+		ModTime: file.UpdatedAt,
+		Open: func(ctx context.Context) (io.ReadCloser, error) {
+			return taskFs.GetDownloadContent(ctx, &file)
+		},
+	}
+}
+
+func (job *CompressTask) Model() *model.Task {
+	return job.record
+}
+
+func (job *CompressTask) SetStatus(status int) {
+	job.record.SetStatus(status)
+}
+
+// DecompressTask 文件解压缩任务
+type DecompressTask struct {
+	User     *model.User
+	Src      string
+	Dst      string
+	Encoding string
+	Conflict filesystem.ConflictPolicy
+
+	record *model.Task
+}
+
+type decompressTaskProps struct {
+	Src      string                    `json:"src"`
+	Dst      string                    `json:"dst"`
+	Encoding string                    `json:"encoding"`
+	Conflict filesystem.ConflictPolicy `json:"conflict"`
+}
+
+// NewDecompressTask 新建一个解压缩任务并落库，实际归档格式通过文件头魔数嗅探得出，不依赖扩展名。
+// conflict 为 merge 时，解压出的条目若其父路径已存在则视为原地写入而非报错，使中断后重新执行的
+// 任务不会在第一个已存在的文件上终止。
+func NewDecompressTask(user *model.User, src, dst, encoding string, conflict filesystem.ConflictPolicy) (Job, error) {
+	props, err := json.Marshal(decompressTaskProps{Src: src, Dst: dst, Encoding: encoding, Conflict: conflict})
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := model.NewTask(user.ID, model.TaskTypeDecompress, string(props))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecompressTask{User: user, Src: src, Dst: dst, Encoding: encoding, Conflict: conflict, record: record}, nil
+}
+
+func (job *DecompressTask) Do() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelRegistry.Store(job.record.ID, cancel)
+	defer cancelRegistry.Delete(job.record.ID)
+
+	taskFs, err := filesystem.NewFileSystem(job.User)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+	defer taskFs.Recycle()
+
+	src, err := taskFs.GetDownloadContentByPath(ctx, job.Src)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+	defer src.Close()
+
+	reader, err := archive.Sniff(src)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+
+	for {
+		if ctx.Err() != nil {
+			job.SetStatus(model.Canceled)
+			return
+		}
+
+		entry, content, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+
+		dst := path.Join(job.Dst, entry.Name)
+		if err := job.writeEntry(ctx, taskFs, dst, entry, content); err != nil {
+			if err == errSkipDecompressEntry {
+				continue
+			}
+			job.SetStatus(model.Error)
+			return
+		}
+	}
+
+	job.SetStatus(model.Complete)
+}
+
+// writeEntry 按 Conflict 策略处理单个解压条目与目标路径的同名冲突：
+// fail 保持历史行为直接报错；skip 跳过该条目；overwrite 直接原地写入；
+// merge 下，父目录已存在视为正常、仅在叶子文件已存在时原地覆盖写入，使中断后重新执行可以继续；
+// rename 在目标路径探测一个不冲突的新名称后再写入。
+// 目录条目在 merge/skip/overwrite 下沿用已存在的同名目录，fail/rename 下创建失败即报错。
+func (job *DecompressTask) writeEntry(ctx context.Context, taskFs *filesystem.FileSystem, dst string, entry archive.DecodedEntry, content io.Reader) error {
+	if entry.IsDir {
+		if err := taskFs.CreateDirectory(ctx, dst); err != nil && job.Conflict != filesystem.ConflictMerge &&
+			job.Conflict != filesystem.ConflictSkip && job.Conflict != filesystem.ConflictOverwrite {
+			return err
+		}
+		return nil
+	}
+
+	exists, _ := taskFs.IsFileExist(dst)
+
+	if exists {
+		switch job.Conflict {
+		case filesystem.ConflictSkip:
+			return errSkipDecompressEntry
+		case filesystem.ConflictOverwrite, filesystem.ConflictMerge:
+			// 原地覆盖写入
+		case filesystem.ConflictRename:
+			name, err := filesystem.ResolveNameCollision(ctx, path.Base(dst), func(ctx context.Context, candidate string) (bool, error) {
+				taken, _ := taskFs.IsFileExist(path.Join(path.Dir(dst), candidate))
+				return taken, nil
+			})
+			if err != nil {
+				return err
+			}
+			dst = path.Join(path.Dir(dst), name)
+		default:
+			return filesystem.ErrObjectExist
+		}
+	}
+
+	uploaded, err := taskFs.Upload(ctx, dst, content)
+	if err != nil {
+		return err
+	}
+	finalizeUpload(uploaded, job.User.ID)
+	return nil
+}
+
+func (job *DecompressTask) Model() *model.Task {
+	return job.record
+}
+
+func (job *DecompressTask) SetStatus(status int) {
+	job.record.SetStatus(status)
+}
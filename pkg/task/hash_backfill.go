@@ -0,0 +1,65 @@
+package task
+
+import (
+	"context"
+	"io"
+
+	model "github.com/cloudreve/Cloudreve/v3/models"
+	"github.com/cloudreve/Cloudreve/v3/pkg/filesystem"
+)
+
+// hashBackfillBatchSize 每次任务执行时补算内容哈希的历史文件数量
+const hashBackfillBatchSize = 100
+
+// HashBackfillTask 为历史遗留、尚未计算内容哈希的文件补算 SHA-256/MD5/BLAKE3
+type HashBackfillTask struct {
+	record *model.Task
+}
+
+// NewHashBackfillTask 新建一个哈希补算任务，ownerID 仅用于任务记录的归属展示，
+// 实际补算范围是全局缺失哈希的文件，与具体用户无关；后台定时调度固定传入 0（系统任务）
+func NewHashBackfillTask(ownerID uint) (Job, error) {
+	record, err := model.NewTask(ownerID, model.TaskTypeHashBackfill, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	return &HashBackfillTask{record: record}, nil
+}
+
+func (job *HashBackfillTask) Do() {
+	files, err := model.GetFilesMissingHash(hashBackfillBatchSize)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+
+	for i := range files {
+		file := &files[i]
+		fs, err := filesystem.NewFileSystem(nil)
+		if err != nil {
+			continue
+		}
+
+		rc, err := fs.GetDownloadContent(context.Background(), file)
+		if err != nil {
+			fs.Recycle()
+			continue
+		}
+
+		hashed := filesystem.WithContentHash(rc, file.SetHashes)
+		_, _ = io.Copy(io.Discard, hashed)
+		rc.Close()
+		fs.Recycle()
+	}
+
+	job.SetStatus(model.Complete)
+}
+
+func (job *HashBackfillTask) Model() *model.Task {
+	return job.record
+}
+
+func (job *HashBackfillTask) SetStatus(status int) {
+	job.record.SetStatus(status)
+}
@@ -0,0 +1,39 @@
+package task
+
+import (
+	model "github.com/cloudreve/Cloudreve/v3/models"
+	"github.com/cloudreve/Cloudreve/v3/pkg/util"
+)
+
+// ReconcileFolderCounters 对标记为 dirty 的目录重新统计 size/child_file_count/child_folder_count，
+// 由定时任务每晚调用一次，修复增量计数可能出现的漂移（例如绕过 hook 的批量 SQL 更新）
+func ReconcileFolderCounters() {
+	dirty, err := model.GetDirtyFolders()
+	if err != nil {
+		util.Log().Warning("无法列出计数失真的目录：%s", err)
+		return
+	}
+
+	for _, folder := range dirty {
+		childFolders, err := model.GetRecursiveChildFolder([]uint{folder.ID}, folder.OwnerID, true)
+		if err != nil {
+			util.Log().Warning("目录[%d]子目录统计失败：%s", folder.ID, err)
+			continue
+		}
+
+		files, err := model.GetChildFilesOfFolders(&childFolders)
+		if err != nil {
+			util.Log().Warning("目录[%d]子文件统计失败：%s", folder.ID, err)
+			continue
+		}
+
+		var size uint64
+		for i := range files {
+			size += files[i].Size
+		}
+
+		if err := folder.SetCounters(size, len(files), len(childFolders)-1); err != nil {
+			util.Log().Warning("目录[%d]计数回写失败：%s", folder.ID, err)
+		}
+	}
+}
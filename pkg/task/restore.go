@@ -0,0 +1,43 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+
+	model "github.com/cloudreve/Cloudreve/v3/models"
+)
+
+// RestoreJob 依据任务记录的类型和落库的游标重建出对应的 Job，用于 worker 重启后的断点续传
+func RestoreJob(record *model.Task) (Job, error) {
+	switch record.Type {
+	case model.TaskTypeMove, model.TaskTypeCopy, model.TaskTypeDelete:
+		var cursor transferCursor
+		if err := json.Unmarshal([]byte(record.Props), &cursor); err != nil {
+			return nil, err
+		}
+
+		user, err := model.GetUserByID(record.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := acquireTransferSlot(user.ID); err != nil {
+			return nil, err
+		}
+
+		base := transferTask{User: &user, cursor: cursor, record: record,
+			progress: Progress{Done: cursor.Done, Total: len(cursor.Dirs) + len(cursor.Items),
+				BytesDone: cursor.BytesDone, BytesTotal: cursor.BytesTotal}}
+
+		switch record.Type {
+		case model.TaskTypeMove:
+			return &MoveTask{base}, nil
+		case model.TaskTypeCopy:
+			return &CopyTask{base}, nil
+		default:
+			return &DeleteTask{base}, nil
+		}
+	default:
+		return nil, fmt.Errorf("无法恢复未知类型的任务：%d", record.Type)
+	}
+}
@@ -0,0 +1,45 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v3/pkg/util"
+)
+
+// hashBackfillInterval 哈希补算任务的提交间隔，每次提交处理一批历史文件
+const hashBackfillInterval = time.Hour
+
+// reconcileInterval 目录计数对账任务的执行间隔，对应文档中所说的"夜间对账"
+const reconcileInterval = 24 * time.Hour
+
+// StartBackgroundJobs 启动哈希补算与目录计数对账的周期调度，由 Init 在创建调度池后调用一次；
+// ctx 被取消时两个调度协程均会退出
+func StartBackgroundJobs(ctx context.Context) {
+	go runPeriodically(ctx, hashBackfillInterval, submitHashBackfill)
+	go runPeriodically(ctx, reconcileInterval, ReconcileFolderCounters)
+}
+
+func runPeriodically(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// submitHashBackfill 提交一个哈希补算任务到调度池，owner 固定为 0 表示系统任务
+func submitHashBackfill() {
+	job, err := NewHashBackfillTask(0)
+	if err != nil {
+		util.Log().Warning("无法创建哈希补算任务：%s", err)
+		return
+	}
+	TaskPoll.Submit(job)
+}
@@ -0,0 +1,87 @@
+package task
+
+import (
+	"context"
+	"sync"
+
+	model "github.com/cloudreve/Cloudreve/v3/models"
+	"github.com/cloudreve/Cloudreve/v3/pkg/util"
+)
+
+// Progress 描述任务当前的执行进度，会被序列化后通过任务列表接口暴露给客户端
+type Progress struct {
+	Done        int    `json:"done"`
+	Total       int    `json:"total"`
+	CurrentPath string `json:"current_path"`
+	BytesDone   uint64 `json:"bytes_done"`
+	BytesTotal  uint64 `json:"bytes_total"`
+}
+
+// Job 是所有异步任务的统一接口，由 TaskPoll 调度执行
+type Job interface {
+	// Do 执行任务，内部需自行处理 panic 恢复与状态持久化
+	Do()
+	// Model 返回任务在数据库中的记录，用于落库与状态查询
+	Model() *model.Task
+	// SetStatus 变更任务状态并持久化
+	SetStatus(status int)
+}
+
+// Progresser 任务可选实现此接口以暴露结构化的执行进度
+type Progresser interface {
+	Progress() Progress
+}
+
+// Pool 是任务调度的执行池，负责限制并发数并派发任务
+type Pool struct {
+	queue chan Job
+	wg    sync.WaitGroup
+}
+
+// TaskPoll 是进程内全局的任务调度池
+var TaskPoll *Pool
+
+// Init 初始化全局任务调度池，worker 为最大并发执行数；同时启动哈希补算与目录计数对账的
+// 后台周期调度，使这两个任务不再需要依赖外部手动触发
+func Init(worker int) {
+	TaskPoll = &Pool{
+		queue: make(chan Job, 1024),
+	}
+
+	for i := 0; i < worker; i++ {
+		go TaskPoll.worker()
+	}
+
+	StartBackgroundJobs(context.Background())
+}
+
+func (pool *Pool) worker() {
+	for job := range pool.queue {
+		job.Do()
+		pool.wg.Done()
+	}
+}
+
+// Submit 提交一个任务到调度池等待执行
+func (pool *Pool) Submit(job Job) {
+	pool.wg.Add(1)
+	pool.queue <- job
+}
+
+// Resume 在进程重启后恢复所有处于执行中状态的任务，使其从记录的游标继续执行
+func Resume(ctx context.Context) {
+	tasks, err := model.GetTasksByStatus(model.Processing)
+	if err != nil {
+		util.Log().Warning("无法恢复未完成任务：%s", err)
+		return
+	}
+
+	for _, t := range tasks {
+		job, err := RestoreJob(&t)
+		if err != nil {
+			util.Log().Warning("无法恢复任务[%d]：%s", t.ID, err)
+			continue
+		}
+		TaskPoll.Submit(job)
+	}
+}
@@ -0,0 +1,461 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	model "github.com/cloudreve/Cloudreve/v3/models"
+	"github.com/cloudreve/Cloudreve/v3/pkg/filesystem"
+)
+
+// MaxConcurrentTransferTasks 单个用户同时运行的长任务（移动/复制/删除）数量上限
+const MaxConcurrentTransferTasks = 3
+
+var (
+	// ErrTooManyActiveTasks 用户已达到长任务并发上限
+	ErrTooManyActiveTasks = errors.New("超出可同时进行的长任务数量限制")
+	// ErrTaskCancelled 任务已被用户取消
+	ErrTaskCancelled = errors.New("任务已被取消")
+
+	activeTransferTasks sync.Map // map[uint]*int32，记录每个用户当前活跃的长任务数
+)
+
+func acquireTransferSlot(userID uint) error {
+	counter, _ := activeTransferTasks.LoadOrStore(userID, new(int32))
+	if atomic.AddInt32(counter.(*int32), 1) > MaxConcurrentTransferTasks {
+		atomic.AddInt32(counter.(*int32), -1)
+		return ErrTooManyActiveTasks
+	}
+	return nil
+}
+
+func releaseTransferSlot(userID uint) {
+	if counter, ok := activeTransferTasks.Load(userID); ok {
+		atomic.AddInt32(counter.(*int32), -1)
+	}
+}
+
+// transferCursor 记录长任务的执行游标，用于 worker 重启后从断点继续
+type transferCursor struct {
+	Dirs       []uint                    `json:"dirs"`
+	Items      []uint                    `json:"items"`
+	Dst        string                    `json:"dst"`
+	SrcDir     string                    `json:"src_dir,omitempty"`
+	Conflict   filesystem.ConflictPolicy `json:"conflict,omitempty"`
+	Done       int                       `json:"done"`
+	BytesDone  uint64                    `json:"bytes_done"`
+	BytesTotal uint64                    `json:"bytes_total"`
+}
+
+// cancelRegistry 维护正在运行任务的取消函数，供外部按任务 ID 主动中止
+var cancelRegistry sync.Map // map[uint]context.CancelFunc
+
+// Cancel 取消一个正在运行的长任务，task ID 对应 model.Task 主键
+func Cancel(taskID uint) bool {
+	if cancel, ok := cancelRegistry.Load(taskID); ok {
+		cancel.(context.CancelFunc)()
+		return true
+	}
+	return false
+}
+
+// transferTask 是 MoveTask/CopyTask/DeleteTask 共用的执行骨架：
+// 维护游标、上报进度、支持取消，并在每完成一项后落库以便断点续传。
+type transferTask struct {
+	User   *model.User
+	cursor transferCursor
+	record *model.Task
+
+	progress Progress
+	mu       sync.Mutex
+}
+
+func (t *transferTask) Model() *model.Task {
+	return t.record
+}
+
+func (t *transferTask) SetStatus(status int) {
+	t.record.SetStatus(status)
+}
+
+func (t *transferTask) Progress() Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.progress
+}
+
+// advance 在完成一个子项后更新进度并把游标写回任务记录，实现断点续传
+func (t *transferTask) advance(currentPath string, bytesDone uint64) error {
+	t.mu.Lock()
+	t.cursor.Done++
+	t.progress.Done = t.cursor.Done
+	t.progress.CurrentPath = currentPath
+	t.cursor.BytesDone += bytesDone
+	t.progress.BytesDone = t.cursor.BytesDone
+	t.mu.Unlock()
+
+	props, err := json.Marshal(t.cursor)
+	if err != nil {
+		return err
+	}
+	return t.record.UpdateProps(string(props))
+}
+
+// MoveTask 可恢复的批量移动任务
+type MoveTask struct {
+	transferTask
+}
+
+// NewMoveTask 新建一个移动任务，threshold 判断应交由调用方（ItemMoveService）决定是否直接同步执行
+func NewMoveTask(user *model.User, srcDir, dst string, dirs, items []uint, conflict filesystem.ConflictPolicy) (Job, error) {
+	if err := acquireTransferSlot(user.ID); err != nil {
+		return nil, err
+	}
+
+	cursor := transferCursor{Dirs: dirs, Items: items, Dst: dst, SrcDir: srcDir, Conflict: conflict}
+	props, err := json.Marshal(cursor)
+	if err != nil {
+		releaseTransferSlot(user.ID)
+		return nil, err
+	}
+
+	record, err := model.NewTask(user.ID, model.TaskTypeMove, string(props))
+	if err != nil {
+		releaseTransferSlot(user.ID)
+		return nil, err
+	}
+
+	bytesTotal := sumFolderAndFileSize(user.ID, dirs, items)
+	cursor.BytesTotal = bytesTotal
+
+	return &MoveTask{transferTask{User: user, cursor: cursor, record: record,
+		progress: Progress{Total: len(dirs) + len(items), BytesTotal: bytesTotal}}}, nil
+}
+
+// sumFolderAndFileSize 汇总 dirs/items 对应目录与文件的大小，用于长任务创建时填充 BytesTotal；
+// 查询失败时返回已查到的部分总和而非中止任务创建，总量仅用于进度展示，不影响任务本身的正确性
+func sumFolderAndFileSize(ownerID uint, dirs, items []uint) uint64 {
+	var total uint64
+	if len(dirs) > 0 {
+		if folders, err := model.GetFoldersByIDs(dirs, ownerID); err == nil {
+			for _, f := range folders {
+				total += f.Size
+			}
+		}
+	}
+	if len(items) > 0 {
+		if files, err := model.GetFilesByIDs(items, ownerID); err == nil {
+			for _, f := range files {
+				total += f.Size
+			}
+		}
+	}
+	return total
+}
+
+func (job *MoveTask) Do() {
+	defer releaseTransferSlot(job.User.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelRegistry.Store(job.record.ID, cancel)
+	defer cancelRegistry.Delete(job.record.ID)
+
+	fs, err := filesystem.NewFileSystem(job.User)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+	defer fs.Recycle()
+
+	// 已完成的项从游标中跳过，实现 worker 重启后的断点续传；
+	// Done 先在 clamp 到 len(Dirs) 之后再切片，避免全部目录完成后 Dirs[Done:] 越界 panic
+	dirs := job.cursor.Dirs[min(job.cursor.Done, len(job.cursor.Dirs)):]
+	items := job.cursor.Items
+	if job.cursor.Done > len(job.cursor.Dirs) {
+		items = job.cursor.Items[job.cursor.Done-len(job.cursor.Dirs):]
+	}
+
+	for _, id := range dirs {
+		if ctx.Err() != nil {
+			job.SetStatus(model.Canceled)
+			return
+		}
+		before, err := model.GetFoldersByIDs([]uint{id}, job.User.ID)
+		if err != nil || len(before) == 0 {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := fs.Move(ctx, []uint{id}, nil, job.cursor.SrcDir, job.cursor.Dst, job.cursor.Conflict); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := applyFolderRelocation(&before[0], job.User.ID, false); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := job.advance(job.cursor.Dst, before[0].Size); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+	}
+	for _, id := range items {
+		if ctx.Err() != nil {
+			job.SetStatus(model.Canceled)
+			return
+		}
+		before, err := model.GetFilesByIDs([]uint{id}, job.User.ID)
+		if err != nil || len(before) == 0 {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := fs.Move(ctx, nil, []uint{id}, job.cursor.SrcDir, job.cursor.Dst, job.cursor.Conflict); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := applyFileRelocation(&before[0], job.User.ID, false); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := job.advance(job.cursor.Dst, before[0].Size); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+	}
+
+	job.SetStatus(model.Complete)
+}
+
+// applyFolderRelocation 在 fs.Move/fs.Copy 已经把目录 before 迁移到新父级之后，
+// 重新查询该目录拿到新的 ParentID，并据此沿旧、新父级链增减计数
+func applyFolderRelocation(before *model.Folder, ownerID uint, isCopy bool) error {
+	after, err := model.GetFoldersByIDs([]uint{before.ID}, ownerID)
+	if err != nil || len(after) == 0 {
+		return err
+	}
+	return model.ApplyFolderRelocationCounters(before, before.ParentID, after[0].ParentID, ownerID, isCopy)
+}
+
+// applyFileRelocation 同 applyFolderRelocation，针对单个文件
+func applyFileRelocation(before *model.File, ownerID uint, isCopy bool) error {
+	after, err := model.GetFilesByIDs([]uint{before.ID}, ownerID)
+	if err != nil || len(after) == 0 {
+		return err
+	}
+	return model.ApplyFileRelocationCounters(before, &before.FolderID, &after[0].FolderID, ownerID, isCopy)
+}
+
+// CopyTask 可恢复的批量复制任务
+type CopyTask struct {
+	transferTask
+}
+
+// NewCopyTask 新建一个复制任务
+func NewCopyTask(user *model.User, srcDir, dst string, dirs, items []uint, conflict filesystem.ConflictPolicy) (Job, error) {
+	if err := acquireTransferSlot(user.ID); err != nil {
+		return nil, err
+	}
+
+	cursor := transferCursor{Dirs: dirs, Items: items, Dst: dst, SrcDir: srcDir, Conflict: conflict}
+	props, err := json.Marshal(cursor)
+	if err != nil {
+		releaseTransferSlot(user.ID)
+		return nil, err
+	}
+
+	record, err := model.NewTask(user.ID, model.TaskTypeCopy, string(props))
+	if err != nil {
+		releaseTransferSlot(user.ID)
+		return nil, err
+	}
+
+	bytesTotal := sumFolderAndFileSize(user.ID, dirs, items)
+	cursor.BytesTotal = bytesTotal
+
+	return &CopyTask{transferTask{User: user, cursor: cursor, record: record,
+		progress: Progress{Total: len(dirs) + len(items), BytesTotal: bytesTotal}}}, nil
+}
+
+func (job *CopyTask) Do() {
+	defer releaseTransferSlot(job.User.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelRegistry.Store(job.record.ID, cancel)
+	defer cancelRegistry.Delete(job.record.ID)
+
+	fs, err := filesystem.NewFileSystem(job.User)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+	defer fs.Recycle()
+
+	dirs := job.cursor.Dirs[min(job.cursor.Done, len(job.cursor.Dirs)):]
+	items := job.cursor.Items
+	if job.cursor.Done > len(job.cursor.Dirs) {
+		items = job.cursor.Items[job.cursor.Done-len(job.cursor.Dirs):]
+	}
+
+	for _, id := range dirs {
+		if ctx.Err() != nil {
+			job.SetStatus(model.Canceled)
+			return
+		}
+		before, err := model.GetFoldersByIDs([]uint{id}, job.User.ID)
+		if err != nil || len(before) == 0 {
+			job.SetStatus(model.Error)
+			return
+		}
+		newID, err := fs.Copy(ctx, []uint{id}, nil, job.cursor.SrcDir, job.cursor.Dst, job.cursor.Conflict)
+		if err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := model.ApplyFolderRelocationCounters(&before[0], nil, &newID, job.User.ID, true); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := job.advance(job.cursor.Dst, before[0].Size); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+	}
+	for _, id := range items {
+		if ctx.Err() != nil {
+			job.SetStatus(model.Canceled)
+			return
+		}
+		before, err := model.GetFilesByIDs([]uint{id}, job.User.ID)
+		if err != nil || len(before) == 0 {
+			job.SetStatus(model.Error)
+			return
+		}
+		newID, err := fs.Copy(ctx, nil, []uint{id}, job.cursor.SrcDir, job.cursor.Dst, job.cursor.Conflict)
+		if err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := model.ApplyFileRelocationCounters(&before[0], nil, &newID, job.User.ID, true); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := job.advance(job.cursor.Dst, before[0].Size); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+	}
+
+	job.SetStatus(model.Complete)
+}
+
+// DeleteTask 可恢复的批量删除任务
+type DeleteTask struct {
+	transferTask
+}
+
+// NewDeleteTask 新建一个删除任务
+func NewDeleteTask(user *model.User, dirs, items []uint) (Job, error) {
+	if err := acquireTransferSlot(user.ID); err != nil {
+		return nil, err
+	}
+
+	cursor := transferCursor{Dirs: dirs, Items: items}
+	props, err := json.Marshal(cursor)
+	if err != nil {
+		releaseTransferSlot(user.ID)
+		return nil, err
+	}
+
+	record, err := model.NewTask(user.ID, model.TaskTypeDelete, string(props))
+	if err != nil {
+		releaseTransferSlot(user.ID)
+		return nil, err
+	}
+
+	bytesTotal := sumFolderAndFileSize(user.ID, dirs, items)
+	cursor.BytesTotal = bytesTotal
+
+	return &DeleteTask{transferTask{User: user, cursor: cursor, record: record,
+		progress: Progress{Total: len(dirs) + len(items), BytesTotal: bytesTotal}}}, nil
+}
+
+func (job *DeleteTask) Do() {
+	defer releaseTransferSlot(job.User.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelRegistry.Store(job.record.ID, cancel)
+	defer cancelRegistry.Delete(job.record.ID)
+
+	fs, err := filesystem.NewFileSystem(job.User)
+	if err != nil {
+		job.SetStatus(model.Error)
+		return
+	}
+	defer fs.Recycle()
+
+	// 逐项删除而非一次性整批调用，使 {done, total, current_path} 能随删除进度逐步推进，
+	// 并让游标在每项完成后落库，支持断点续传
+	dirs := job.cursor.Dirs[min(job.cursor.Done, len(job.cursor.Dirs)):]
+	items := job.cursor.Items
+	if job.cursor.Done > len(job.cursor.Dirs) {
+		items = job.cursor.Items[job.cursor.Done-len(job.cursor.Dirs):]
+	}
+
+	for _, id := range dirs {
+		if ctx.Err() != nil {
+			job.SetStatus(model.Canceled)
+			return
+		}
+		before, err := model.GetFoldersByIDs([]uint{id}, job.User.ID)
+		if err != nil || len(before) == 0 {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := fs.Delete(ctx, []uint{id}, nil, false); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := model.ApplyFolderRelocationCounters(&before[0], before[0].ParentID, nil, job.User.ID, false); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := job.advance("", before[0].Size); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+	}
+	for _, id := range items {
+		if ctx.Err() != nil {
+			job.SetStatus(model.Canceled)
+			return
+		}
+		before, err := model.GetFilesByIDs([]uint{id}, job.User.ID)
+		if err != nil || len(before) == 0 {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := fs.Delete(ctx, nil, []uint{id}, false); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := model.ApplyFileRelocationCounters(&before[0], &before[0].FolderID, nil, job.User.ID, false); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+		if err := job.advance("", before[0].Size); err != nil {
+			job.SetStatus(model.Error)
+			return
+		}
+	}
+
+	job.SetStatus(model.Complete)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -2,14 +2,18 @@ package explorer
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/gob"
+	"encoding/hex"
 	"fmt"
 	"math"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
 	model "github.com/cloudreve/Cloudreve/v3/models"
+	"github.com/cloudreve/Cloudreve/v3/pkg/archive"
 	"github.com/cloudreve/Cloudreve/v3/pkg/auth"
 	"github.com/cloudreve/Cloudreve/v3/pkg/cache"
 	"github.com/cloudreve/Cloudreve/v3/pkg/filesystem"
@@ -25,12 +29,16 @@ type ItemMoveService struct {
 	SrcDir string        `json:"src_dir" binding:"required,min=1,max=65535"`
 	Src    ItemIDService `json:"src"`
 	Dst    string        `json:"dst" binding:"required,min=1,max=65535"`
+	// Conflict 目标路径同名冲突时的处理策略，为空时保持历史行为（fail）
+	Conflict string `json:"conflict" binding:"omitempty,oneof=fail skip overwrite rename merge"`
 }
 
 // ItemRenameService 处理多文件/目录重命名
 type ItemRenameService struct {
 	Src     ItemIDService `json:"src"`
 	NewName string        `json:"new_name" binding:"required,min=1,max=255"`
+	// Conflict 目标路径同名冲突时的处理策略，为空时保持历史行为（fail）
+	Conflict string `json:"conflict" binding:"omitempty,oneof=fail skip overwrite rename"`
 }
 
 // ItemService 处理多文件/目录相关服务
@@ -43,6 +51,8 @@ type ItemService struct {
 type ItemIDService struct {
 	Items  []string `json:"items"`
 	Dirs   []string `json:"dirs"`
+	// Format 打包下载使用的归档格式，为空时默认为 zip
+	Format string `json:"format" binding:"omitempty,oneof=zip tar tar.gz tar.zst"`
 	Source *ItemService
 }
 
@@ -51,6 +61,8 @@ type ItemCompressService struct {
 	Src  ItemIDService `json:"src"`
 	Dst  string        `json:"dst" binding:"required,min=1,max=65535"`
 	Name string        `json:"name" binding:"required,min=1,max=255"`
+	// Format 服务端压缩任务使用的归档格式，为空时默认为 zip
+	Format string `json:"format" binding:"omitempty,oneof=zip tar tar.gz tar.zst"`
 }
 
 // ItemDecompressService 文件解压缩任务服务
@@ -58,6 +70,9 @@ type ItemDecompressService struct {
 	Src      string `json:"src"`
 	Dst      string `json:"dst" binding:"required,min=1,max=65535"`
 	Encoding string `json:"encoding"`
+	// Conflict 解压出的条目与已存在文件同名时的处理策略，为空时保持历史行为（fail）；
+	// merge 策略下，父路径已存在的条目按原地写入处理而非直接报错，使半途中断的任务可以重新执行
+	Conflict string `json:"conflict" binding:"omitempty,oneof=fail skip overwrite rename merge"`
 }
 
 // ItemPropertyService 获取对象属性服务
@@ -67,6 +82,17 @@ type ItemPropertyService struct {
 	IsFolder  bool   `form:"is_folder"`
 }
 
+// BulkPropertyItem 批量属性查询中的单个查询目标
+type BulkPropertyItem struct {
+	ID       string `json:"id" binding:"required"`
+	IsFolder bool   `json:"is_folder"`
+}
+
+// ItemBulkPropertyService 批量获取对象属性服务，单次请求内对所有目录共用一次递归遍历
+type ItemBulkPropertyService struct {
+	Items []BulkPropertyItem `json:"items" binding:"required,min=1,max=200"`
+}
+
 func init() {
 	gob.Register(ItemIDService{})
 }
@@ -128,9 +154,12 @@ func (service *ItemDecompressService) CreateDecompressTask(c *gin.Context) seria
 		return serializer.Err(serializer.CodeParamErr, "文件太大", nil)
 	}
 
-	// 支持的压缩格式后缀
+	// 支持的压缩格式后缀，真正的格式判定由 task.NewDecompressTask 通过文件头魔数完成，
+	// 这里仅做一次快速的文件名预检查，避免明显不支持的文件进入任务队列；
+	// 后缀集合需要与 archive.Sniff 实际能识别的魔数保持一致——.rar/.xz/单体 .gz 均无对应
+	// 解码实现，混进来只会在 tar 解析阶段报出一个让人摸不着头脑的错误，不如直接在这里拒绝
 	var (
-		suffixes = []string{".zip", ".gz", ".xz", ".tar", ".rar"}
+		suffixes = []string{".zip", ".tar", ".tar.gz", ".tar.zst"}
 		matched  bool
 	)
 	for _, suffix := range suffixes {
@@ -144,7 +173,8 @@ func (service *ItemDecompressService) CreateDecompressTask(c *gin.Context) seria
 	}
 
 	// 创建任务
-	job, err := task.NewDecompressTask(fs.User, service.Src, service.Dst, service.Encoding)
+	job, err := task.NewDecompressTask(fs.User, service.Src, service.Dst, service.Encoding,
+		filesystem.NormalizeConflictPolicy(service.Conflict))
 	if err != nil {
 		return serializer.Err(serializer.CodeNotSet, "任务创建失败", err)
 	}
@@ -168,9 +198,13 @@ func (service *ItemCompressService) CreateCompressTask(c *gin.Context) serialize
 		return serializer.Err(serializer.CodeGroupNotAllowed, "当前用户组无法进行此操作", nil)
 	}
 
-	// 补齐压缩文件扩展名（如果没有）
-	if !strings.HasSuffix(service.Name, ".zip") {
-		service.Name += ".zip"
+	// 归档格式默认为 zip，校验并补齐压缩文件扩展名（如果没有）
+	format := archive.Format(service.Format)
+	if format == "" {
+		format = archive.Zip
+	}
+	if !strings.HasSuffix(service.Name, format.Suffix()) {
+		service.Name += format.Suffix()
 	}
 
 	// 存放目录是否存在，是否重名
@@ -213,16 +247,15 @@ func (service *ItemCompressService) CreateCompressTask(c *gin.Context) serialize
 		return serializer.Err(serializer.CodeParamErr, "文件太大", nil)
 	}
 
-	// 按照平均压缩率计算用户空间是否足够
-	compressRatio := 0.4
-	spaceNeeded := uint64(math.Round(float64(totalSize) * compressRatio))
+	// 按照该格式的平均压缩率计算用户空间是否足够
+	spaceNeeded := uint64(math.Round(float64(totalSize) * compressRatioOf(format)))
 	if fs.User.GetRemainingCapacity() < spaceNeeded {
 		return serializer.Err(serializer.CodeParamErr, "剩余空间不足", err)
 	}
 
 	// 创建任务
 	job, err := task.NewCompressTask(fs.User, path.Join(service.Dst, service.Name), service.Src.Raw().Dirs,
-		service.Src.Raw().Items)
+		service.Src.Raw().Items, format)
 	if err != nil {
 		return serializer.Err(serializer.CodeNotSet, "任务创建失败", err)
 	}
@@ -232,6 +265,20 @@ func (service *ItemCompressService) CreateCompressTask(c *gin.Context) serialize
 
 }
 
+// compressRatioOf 返回各归档格式的平均压缩率估算值，用于压缩任务创建前的空间预检查
+func compressRatioOf(format archive.Format) float64 {
+	switch format {
+	case archive.Tar:
+		return 1
+	case archive.TarGz, archive.Zip:
+		return 0.4
+	case archive.TarZst:
+		return 0.35
+	default:
+		return 0.4
+	}
+}
+
 // Archive 创建归档
 func (service *ItemIDService) Archive(ctx context.Context, c *gin.Context) serializer.Response {
 	// 创建文件系统
@@ -246,6 +293,13 @@ func (service *ItemIDService) Archive(ctx context.Context, c *gin.Context) seria
 		return serializer.Err(serializer.CodeGroupNotAllowed, "当前用户组无法进行此操作", nil)
 	}
 
+	// 归档格式默认为 zip，由下载路由依据文件名后缀决定响应的 Content-Type
+	format := archive.Format(service.Format)
+	if format == "" {
+		format = archive.Zip
+	}
+	service.Format = string(format)
+
 	// 创建打包下载会话
 	ttl := model.GetIntSetting("archive_timeout", 30)
 	downloadSessionID := util.RandStringRunes(16)
@@ -253,7 +307,7 @@ func (service *ItemIDService) Archive(ctx context.Context, c *gin.Context) seria
 	cache.Set("archive_user_"+downloadSessionID, *fs.User, ttl)
 	signURL, err := auth.SignURI(
 		auth.General,
-		fmt.Sprintf("/api/v3/file/archive/%s/archive.zip", downloadSessionID),
+		fmt.Sprintf("/api/v3/file/archive/%s/archive%s", downloadSessionID, format.Suffix()),
 		int64(ttl),
 	)
 
@@ -263,6 +317,38 @@ func (service *ItemIDService) Archive(ctx context.Context, c *gin.Context) seria
 	}
 }
 
+// asyncTaskThreshold 判断一次批量操作是否应提交为异步任务而非在请求内同步完成：
+// 子项总数或累计大小超出限制时返回 true，避免大型目录树拖垮 HTTP 请求
+func asyncTaskThreshold(dirs, items []uint) (bool, error) {
+	folders, err := model.GetRecursiveChildFolder(dirs, 0, false)
+	if err != nil {
+		return false, err
+	}
+
+	files, err := model.GetChildFilesOfFolders(&folders)
+	if err != nil {
+		return false, err
+	}
+
+	fileCountThreshold := model.GetIntSetting("async_task_file_count_threshold", 1000)
+	sizeThreshold := model.GetIntSetting("async_task_size_threshold", 1073741824) // 1GB
+
+	total := len(items) + len(files)
+	if total > fileCountThreshold {
+		return true, nil
+	}
+
+	var totalSize uint64
+	for i := 0; i < len(files); i++ {
+		totalSize += files[i].Size
+	}
+	if totalSize > uint64(sizeThreshold) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
 // Delete 删除对象
 func (service *ItemIDService) Delete(ctx context.Context, c *gin.Context) serializer.Response {
 	// 创建文件系统
@@ -272,13 +358,41 @@ func (service *ItemIDService) Delete(ctx context.Context, c *gin.Context) serial
 	}
 	defer fs.Recycle()
 
-	// 删除对象
 	items := service.Raw()
+
+	// 选中内容较多时提交为异步任务，避免阻塞请求
+	if async, err := asyncTaskThreshold(items.Dirs, items.Items); err == nil && async {
+		job, err := task.NewDeleteTask(fs.User, items.Dirs, items.Items)
+		if err != nil {
+			return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+		}
+		task.TaskPoll.Submit(job)
+		return serializer.Response{Code: 0, Data: job.Model().ID}
+	}
+
+	// 删除前先记下每个对象所在的父目录及自身聚合计数，删除成功后据此扣减父级链上的增量计数
+	beforeFolders, err := model.GetFoldersByIDs(items.Dirs, fs.User.ID)
+	if err != nil {
+		return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+	}
+	beforeFiles, err := model.GetFilesByIDs(items.Items, fs.User.ID)
+	if err != nil {
+		return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+	}
+
+	// 删除对象
 	err = fs.Delete(ctx, items.Dirs, items.Items, false)
 	if err != nil {
 		return serializer.Err(serializer.CodeNotSet, err.Error(), err)
 	}
 
+	for i := range beforeFolders {
+		_ = model.ApplyFolderRelocationCounters(&beforeFolders[i], beforeFolders[i].ParentID, nil, fs.User.ID, false)
+	}
+	for i := range beforeFiles {
+		_ = model.ApplyFileRelocationCounters(&beforeFiles[i], &beforeFiles[i].FolderID, nil, fs.User.ID, false)
+	}
+
 	return serializer.Response{
 		Code: 0,
 	}
@@ -294,13 +408,59 @@ func (service *ItemMoveService) Move(ctx context.Context, c *gin.Context) serial
 	}
 	defer fs.Recycle()
 
-	// 移动对象
 	items := service.Src.Raw()
-	err = fs.Move(ctx, items.Dirs, items.Items, service.SrcDir, service.Dst)
+
+	// 选中内容较多时提交为异步任务，避免阻塞请求
+	if async, err := asyncTaskThreshold(items.Dirs, items.Items); err == nil && async {
+		job, err := task.NewMoveTask(fs.User, service.SrcDir, service.Dst, items.Dirs, items.Items,
+			filesystem.NormalizeConflictPolicy(service.Conflict))
+		if err != nil {
+			return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+		}
+		task.TaskPoll.Submit(job)
+		return serializer.Response{Code: 0, Data: job.Model().ID}
+	}
+
+	// 移动前记下每个对象原先所在的父目录，移动成功后对比新的父目录，沿新旧父级链增减计数
+	beforeFolders, err := model.GetFoldersByIDs(items.Dirs, fs.User.ID)
+	if err != nil {
+		return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+	}
+	beforeFiles, err := model.GetFilesByIDs(items.Items, fs.User.ID)
+	if err != nil {
+		return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+	}
+
+	// 移动对象
+	err = fs.Move(ctx, items.Dirs, items.Items, service.SrcDir, service.Dst,
+		filesystem.NormalizeConflictPolicy(service.Conflict))
 	if err != nil {
 		return serializer.Err(serializer.CodeNotSet, err.Error(), err)
 	}
 
+	if afterFolders, err := model.GetFoldersByIDs(items.Dirs, fs.User.ID); err == nil {
+		afterByID := make(map[uint]*model.Folder, len(afterFolders))
+		for i := range afterFolders {
+			afterByID[afterFolders[i].ID] = &afterFolders[i]
+		}
+		for i := range beforeFolders {
+			if after, ok := afterByID[beforeFolders[i].ID]; ok {
+				_ = model.ApplyFolderRelocationCounters(&beforeFolders[i], beforeFolders[i].ParentID, after.ParentID, fs.User.ID, false)
+			}
+		}
+	}
+	if afterFiles, err := model.GetFilesByIDs(items.Items, fs.User.ID); err == nil {
+		afterByID := make(map[uint]*model.File, len(afterFiles))
+		for i := range afterFiles {
+			afterByID[afterFiles[i].ID] = &afterFiles[i]
+		}
+		for i := range beforeFiles {
+			if after, ok := afterByID[beforeFiles[i].ID]; ok {
+				_ = model.ApplyFileRelocationCounters(&beforeFiles[i], &beforeFiles[i].FolderID, &after.FolderID, fs.User.ID, false)
+			}
+		}
+	}
+
 	return serializer.Response{
 		Code: 0,
 	}
@@ -321,12 +481,47 @@ func (service *ItemMoveService) Copy(ctx context.Context, c *gin.Context) serial
 	}
 	defer fs.Recycle()
 
+	items := service.Src.Raw()
+
+	// 选中内容较多时提交为异步任务，避免阻塞请求
+	if async, err := asyncTaskThreshold(items.Dirs, items.Items); err == nil && async {
+		job, err := task.NewCopyTask(fs.User, service.SrcDir, service.Dst, items.Dirs, items.Items,
+			filesystem.NormalizeConflictPolicy(service.Conflict))
+		if err != nil {
+			return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+		}
+		task.TaskPoll.Submit(job)
+		return serializer.Response{Code: 0, Data: job.Model().ID}
+	}
+
+	// 复制前记下被复制对象自身的聚合计数（复制不影响源端，只需用于计算目标端的增量）
+	var beforeFolder *model.Folder
+	var beforeFile *model.File
+	if len(items.Dirs) == 1 {
+		if folders, err := model.GetFoldersByIDs(items.Dirs, fs.User.ID); err == nil && len(folders) == 1 {
+			beforeFolder = &folders[0]
+		}
+	}
+	if len(items.Items) == 1 {
+		if files, err := model.GetFilesByIDs(items.Items, fs.User.ID); err == nil && len(files) == 1 {
+			beforeFile = &files[0]
+		}
+	}
+
 	// 复制对象
-	err = fs.Copy(ctx, service.Src.Raw().Dirs, service.Src.Raw().Items, service.SrcDir, service.Dst)
+	newID, err := fs.Copy(ctx, items.Dirs, items.Items, service.SrcDir, service.Dst,
+		filesystem.NormalizeConflictPolicy(service.Conflict))
 	if err != nil {
 		return serializer.Err(serializer.CodeNotSet, err.Error(), err)
 	}
 
+	if beforeFolder != nil {
+		_ = model.ApplyFolderRelocationCounters(beforeFolder, nil, &newID, fs.User.ID, true)
+	}
+	if beforeFile != nil {
+		_ = model.ApplyFileRelocationCounters(beforeFile, nil, &newID, fs.User.ID, true)
+	}
+
 	return serializer.Response{
 		Code: 0,
 	}
@@ -347,8 +542,60 @@ func (service *ItemRenameService) Rename(ctx context.Context, c *gin.Context) se
 	}
 	defer fs.Recycle()
 
+	items := service.Src.Raw()
+	newName := service.NewName
+	policy := filesystem.NormalizeConflictPolicy(service.Conflict)
+
+	// rename/skip 策略都需要先知道重命名对象所在的父目录下是否已有同名对象，
+	// 由于重命名不跨目录，父目录直接取自对象自身的 ParentID/FolderID，无需解析路径
+	if policy == filesystem.ConflictRename || policy == filesystem.ConflictSkip {
+		var parentID *uint
+		var ownerID uint
+		var taken bool
+
+		if len(items.Dirs) == 1 {
+			folders, err := model.GetFoldersByIDs(items.Dirs, fs.User.ID)
+			if err != nil || len(folders) == 0 {
+				return serializer.Err(serializer.CodeNotSet, "对象不存在", err)
+			}
+			parentID, ownerID = folders[0].ParentID, folders[0].OwnerID
+			taken, err = model.FolderNameExists(parentID, ownerID, newName)
+			if err != nil {
+				return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+			}
+		} else if len(items.Items) == 1 {
+			files, err := model.GetFilesByIDs(items.Items, fs.User.ID)
+			if err != nil || len(files) == 0 {
+				return serializer.Err(serializer.CodeNotSet, "对象不存在", err)
+			}
+			folderID := files[0].FolderID
+			parentID, ownerID = &folderID, files[0].UserID
+			taken, err = model.FileNameExists(folderID, ownerID, newName)
+			if err != nil {
+				return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+			}
+		}
+
+		if taken {
+			if policy == filesystem.ConflictSkip {
+				return serializer.Response{Code: 0}
+			}
+
+			// ConflictRename：探测一个不冲突的新名称后再继续重命名
+			newName, err = filesystem.ResolveNameCollision(ctx, newName, func(ctx context.Context, candidate string) (bool, error) {
+				if len(items.Dirs) == 1 {
+					return model.FolderNameExists(parentID, ownerID, candidate)
+				}
+				return model.FileNameExists(*parentID, ownerID, candidate)
+			})
+			if err != nil {
+				return serializer.Err(serializer.CodeNotSet, err.Error(), err)
+			}
+		}
+	}
+
 	// 重命名对象
-	err = fs.Rename(ctx, service.Src.Raw().Dirs, service.Src.Raw().Items, service.NewName)
+	err = fs.Rename(ctx, items.Dirs, items.Items, newName, policy)
 	if err != nil {
 		return serializer.Err(serializer.CodeNotSet, err.Error(), err)
 	}
@@ -382,6 +629,9 @@ func (service *ItemPropertyService) GetProperty(ctx context.Context, c *gin.Cont
 		props.UpdatedAt = file[0].UpdatedAt
 		props.Policy = file[0].GetPolicy().Name
 		props.Size = file[0].Size
+		props.HashSHA256 = file[0].SHA256Hash
+		props.HashMD5 = file[0].MD5Hash
+		props.HashBLAKE3 = file[0].BLAKE3Hash
 
 		// 查找父目录
 		if service.TraceRoot {
@@ -418,24 +668,36 @@ func (service *ItemPropertyService) GetProperty(ctx context.Context, c *gin.Cont
 			return serializer.Response{Data: res}
 		}
 
-		// 统计子目录
-		childFolders, err := model.GetRecursiveChildFolder([]uint{folder[0].ID},
-			user.ID, true)
-		if err != nil {
-			return serializer.DBErr("无法列取子目录", err)
-		}
-		props.ChildFolderNum = len(childFolders) - 1
+		if folder[0].CountersValid() {
+			// 增量计数可信，直接读取 O(1) 的汇总列，不为了汇总哈希单独遍历整棵子树——
+			// HashSHA256 留空，与 BulkProperty 的快路径行为一致；只有计数失真、已经付出
+			// 一次全量遍历代价时，才顺带算出哈希并随本次结果写入下面的缓存
+			props.ChildFolderNum = folder[0].ChildFolderCount
+			props.ChildFileNum = folder[0].ChildFileCount
+			props.Size = folder[0].Size
+		} else {
+			// 增量计数已失真（如批量 SQL 更新绕过了 hook），按本次遍历结果重新统计并修复计数
+			childFolders, err := model.GetRecursiveChildFolder([]uint{folder[0].ID}, user.ID, true)
+			if err != nil {
+				return serializer.DBErr("无法列取子目录", err)
+			}
 
-		// 统计子文件
-		files, err := model.GetChildFilesOfFolders(&childFolders)
-		if err != nil {
-			return serializer.DBErr("无法列取子文件", err)
-		}
+			files, err := model.GetChildFilesOfFolders(&childFolders)
+			if err != nil {
+				return serializer.DBErr("无法列取子文件", err)
+			}
 
-		// 统计子文件个数和大小
-		props.ChildFileNum = len(files)
-		for i := 0; i < len(files); i++ {
-			props.Size += files[i].Size
+			props.ChildFolderNum = len(childFolders) - 1
+			props.ChildFileNum = len(files)
+			for i := 0; i < len(files); i++ {
+				props.Size += files[i].Size
+			}
+
+			if err := folder[0].SetCounters(props.Size, props.ChildFileNum, props.ChildFolderNum); err != nil {
+				util.Log().Warning("无法修复目录[%d]的增量计数：%s", folder[0].ID, err)
+			}
+
+			props.HashSHA256 = folderRollupHash(files)
 		}
 
 		// 查找父目录
@@ -457,3 +719,203 @@ func (service *ItemPropertyService) GetProperty(ctx context.Context, c *gin.Cont
 		Data: props,
 	}
 }
+
+// BulkProperty 批量获取对象属性，所有目录共用同一次递归子树遍历，
+// 避免像逐个调用 GetProperty 那样对存在公共子树的多个目录重复统计
+func (service *ItemBulkPropertyService) BulkProperty(ctx context.Context, c *gin.Context) serializer.Response {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(*model.User)
+
+	var (
+		folderIDs    []uint
+		fileIDs      []uint
+		folderKeyMap = make(map[uint]string)
+		fileKeyMap   = make(map[uint]string)
+	)
+	for _, item := range service.Items {
+		if item.IsFolder {
+			if id, err := hashid.DecodeHashID(item.ID, hashid.FolderID); err == nil {
+				folderIDs = append(folderIDs, id)
+				folderKeyMap[id] = item.ID
+			}
+		} else {
+			if id, err := hashid.DecodeHashID(item.ID, hashid.FileID); err == nil {
+				fileIDs = append(fileIDs, id)
+				fileKeyMap[id] = item.ID
+			}
+		}
+	}
+
+	res := make(map[string]serializer.ObjectProps, len(service.Items))
+	now := time.Now()
+
+	if len(fileIDs) > 0 {
+		files, err := model.GetFilesByIDs(fileIDs, user.ID)
+		if err != nil {
+			return serializer.DBErr("找不到文件", err)
+		}
+		for _, file := range files {
+			res[fileKeyMap[file.ID]] = serializer.ObjectProps{
+				CreatedAt:  file.CreatedAt,
+				UpdatedAt:  file.UpdatedAt,
+				Policy:     file.GetPolicy().Name,
+				Size:       file.Size,
+				QueryDate:  now,
+				HashSHA256: file.SHA256Hash,
+				HashMD5:    file.MD5Hash,
+				HashBLAKE3: file.BLAKE3Hash,
+			}
+		}
+	}
+
+	if len(folderIDs) > 0 {
+		folders, err := model.GetFoldersByIDs(folderIDs, user.ID)
+		if err != nil {
+			return serializer.DBErr("找不到目录", err)
+		}
+
+		// 对所有请求中失真的目录，一次性递归遍历其子树，公共子树只会被遍历一次
+		var dirtyIDs []uint
+		for _, folder := range folders {
+			if !folder.CountersValid() {
+				dirtyIDs = append(dirtyIDs, folder.ID)
+			}
+		}
+
+		var childFolders []model.Folder
+		var childFiles []model.File
+		if len(dirtyIDs) > 0 {
+			childFolders, err = model.GetRecursiveChildFolder(dirtyIDs, user.ID, true)
+			if err != nil {
+				return serializer.DBErr("无法列取子目录", err)
+			}
+			childFiles, err = model.GetChildFilesOfFolders(&childFolders)
+			if err != nil {
+				return serializer.DBErr("无法列取子文件", err)
+			}
+		}
+
+		for _, folder := range folders {
+			props := serializer.ObjectProps{
+				CreatedAt: folder.CreatedAt,
+				UpdatedAt: folder.UpdatedAt,
+				QueryDate: now,
+			}
+
+			if folder.CountersValid() {
+				props.ChildFolderNum = folder.ChildFolderCount
+				props.ChildFileNum = folder.ChildFileCount
+				props.Size = folder.Size
+
+				// 计数可信时不为了哈希单独触发一次递归遍历，复用 GetProperty 写入的
+				// folder_props_%d 缓存；缓存未命中（尚未被 GetProperty 访问过）则哈希留空，
+				// 与全量遍历得到的 HashSHA256 不一致属于可接受的缓存未预热状态，而非数据错误
+				if cacheRes, ok := cache.Get(fmt.Sprintf("folder_props_%d", folder.ID)); ok {
+					if cached, ok := cacheRes.(serializer.ObjectProps); ok {
+						props.HashSHA256 = cached.HashSHA256
+					}
+				}
+			} else {
+				subFolders, subFiles := filterSubtree(folder, childFolders, childFiles)
+				props.ChildFolderNum = len(subFolders) - 1
+				props.ChildFileNum = len(subFiles)
+				for i := range subFiles {
+					props.Size += subFiles[i].Size
+				}
+				if err := folder.SetCounters(props.Size, props.ChildFileNum, props.ChildFolderNum); err != nil {
+					util.Log().Warning("无法修复目录[%d]的增量计数：%s", folder.ID, err)
+				}
+				// 计数失真时本就需要遍历子树，顺带算出汇总哈希；计数可信的目录沿用 O(1) 路径，
+				// 不在批量接口里为了哈希而强制触发一次递归遍历
+				props.HashSHA256 = folderRollupHash(subFiles)
+			}
+
+			res[folderKeyMap[folder.ID]] = props
+		}
+	}
+
+	return serializer.Response{
+		Code: 0,
+		Data: res,
+	}
+}
+
+// folderRollupHash 计算目录子树的 Merkle 式汇总哈希：按文件名（同名时按哈希）排序后，
+// 对每个文件的 "name||sha256" 依次拼接求 SHA-256，使子树内容发生任何变化都会改变汇总哈希
+func folderRollupHash(files []model.File) string {
+	sorted := make([]model.File, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].SHA256Hash < sorted[j].SHA256Hash
+	})
+
+	h := sha256.New()
+	for _, file := range sorted {
+		h.Write([]byte(file.Name))
+		h.Write([]byte("||"))
+		h.Write([]byte(file.SHA256Hash))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// filterSubtree 从一次递归遍历得到的全量结果中，筛选出属于某个具体目录的子树部分，
+// 使多个存在公共前缀的目录可以共用同一次 GetRecursiveChildFolder 调用
+func filterSubtree(root model.Folder, folders []model.Folder, files []model.File) ([]model.Folder, []model.File) {
+	prefix := path.Join(root.Position, root.Name)
+	folderIDs := map[uint]bool{root.ID: true}
+
+	subFolders := []model.Folder{root}
+	for _, folder := range folders {
+		if folder.ID == root.ID {
+			continue
+		}
+		if folder.Position == prefix || strings.HasPrefix(folder.Position, prefix+"/") {
+			subFolders = append(subFolders, folder)
+			folderIDs[folder.ID] = true
+		}
+	}
+
+	subFiles := make([]model.File, 0, len(files))
+	for _, file := range files {
+		if folderIDs[file.FolderID] {
+			subFiles = append(subFiles, file)
+		}
+	}
+
+	return subFolders, subFiles
+}
+
+// ItemTaskService 处理长任务（移动/复制/删除/压缩/解压缩等）的取消
+type ItemTaskService struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// Cancel 取消一个尚未完成的长任务，仅任务所有者可操作
+func (service *ItemTaskService) Cancel(c *gin.Context) serializer.Response {
+	userCtx, _ := c.Get("user")
+	user := userCtx.(*model.User)
+
+	id, err := hashid.DecodeHashID(service.ID, hashid.TaskID)
+	if err != nil {
+		return serializer.Err(serializer.CodeNotFound, "任务不存在", err)
+	}
+
+	record, err := model.GetTaskByID(id, user.ID)
+	if err != nil {
+		return serializer.DBErr("找不到任务", err)
+	}
+
+	if record.Status != model.Processing {
+		return serializer.Err(serializer.CodeNotFound, "任务已结束，无法取消", nil)
+	}
+
+	if !task.Cancel(record.ID) {
+		return serializer.Err(serializer.CodeNotFound, "任务当前不在执行中", nil)
+	}
+
+	return serializer.Response{}
+}
@@ -0,0 +1,74 @@
+package explorer
+
+import (
+	"testing"
+
+	model "github.com/cloudreve/Cloudreve/v3/models"
+	"github.com/cloudreve/Cloudreve/v3/pkg/archive"
+)
+
+func TestCompressRatioOf(t *testing.T) {
+	tests := map[archive.Format]float64{
+		archive.Tar:    1,
+		archive.TarGz:  0.4,
+		archive.Zip:    0.4,
+		archive.TarZst: 0.35,
+		"":             0.4,
+	}
+
+	for format, want := range tests {
+		if got := compressRatioOf(format); got != want {
+			t.Errorf("compressRatioOf(%q) = %v, want %v", format, got, want)
+		}
+	}
+}
+
+func TestFolderRollupHash(t *testing.T) {
+	a := folderRollupHash([]model.File{
+		{Name: "b.txt", SHA256Hash: "bbb"},
+		{Name: "a.txt", SHA256Hash: "aaa"},
+	})
+	b := folderRollupHash([]model.File{
+		{Name: "a.txt", SHA256Hash: "aaa"},
+		{Name: "b.txt", SHA256Hash: "bbb"},
+	})
+	if a != b {
+		t.Errorf("folderRollupHash should be order-independent, got %q != %q", a, b)
+	}
+
+	changed := folderRollupHash([]model.File{
+		{Name: "a.txt", SHA256Hash: "aaa"},
+		{Name: "b.txt", SHA256Hash: "ccc"},
+	})
+	if a == changed {
+		t.Errorf("folderRollupHash should change when file content hash changes")
+	}
+}
+
+func TestFilterSubtree(t *testing.T) {
+	root := model.Folder{ID: 1, Name: "root", Position: "/home"}
+	folders := []model.Folder{
+		root,
+		{ID: 2, Name: "child", Position: "/home/root"},
+		{ID: 3, Name: "sibling", Position: "/home"},
+	}
+	files := []model.File{
+		{Name: "in-root.txt", FolderID: 1},
+		{Name: "in-child.txt", FolderID: 2},
+		{Name: "in-sibling.txt", FolderID: 3},
+	}
+
+	subFolders, subFiles := filterSubtree(root, folders, files)
+
+	if len(subFolders) != 2 {
+		t.Fatalf("subFolders = %+v, want 2 entries (root + child)", subFolders)
+	}
+	if len(subFiles) != 2 {
+		t.Fatalf("subFiles = %+v, want 2 entries (in-root + in-child)", subFiles)
+	}
+	for _, f := range subFiles {
+		if f.Name == "in-sibling.txt" {
+			t.Errorf("filterSubtree leaked sibling's file into subtree result")
+		}
+	}
+}